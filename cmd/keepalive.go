@@ -1,13 +1,16 @@
 package cmd
 
 import (
-	"errors"
+	"context"
+	"encoding/json"
 	"log"
 	"net/http"
-	"strings"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
-	"github.com/iqlusioninc/relayer/relayer"
+	"github.com/iqlusioninc/relayer/monitor"
 
 	"github.com/spf13/cobra"
 
@@ -16,166 +19,150 @@ import (
 )
 
 /*
-This command runs update-client at a preset interval.
+This command runs update-client at a preset interval for one or more paths.
 
-Prometheus endpoint:
-0.0.0.0:20202/metrics
+Endpoints (on --listen, 0.0.0.0:20202 by default):
+/metrics: Prometheus metrics, see below
+/healthz: 200 once the update loops have been launched
+/readyz:  200 once every path has updated successfully within 2x its interval, else 503
+/status:  JSON array of per-path status, also written to --status-file if set
 
 Prometheus Metrics:
-script_health: 1.0 if updates are running successfully, else 0.0
-last_update: unix time stamp in seconds of when the last update succeded
-channel_open: 1.0 if channel is open, else 0.0
-unrelayed_sequences: number of unrelayed sequences or negative if error occured (check logs)
+GoZ_relayer_script_health{path,chain,client}: 1.0 if updates are running successfully, else 0.0
+GoZ_relayer_last_update{path,chain,client}: unix timestamp in seconds of when the last update succeeded
+GoZ_relayer_unrelayed_sequences{path}: number of unrelayed sequences or negative if an error occurred
+GoZ_relayer_relayed_packets_total{path,direction,result}: packets submitted by --auto-relay
+GoZ_relayer_relay_duration_seconds{path}: time taken to relay a batch of packets
+GoZ_relayer_client_trust_period_remaining_seconds{chain,client}: seconds until the client expires
+GoZ_relayer_chain_latest_height{chain}: latest height observed for the chain
+GoZ_relayer_header_lag_blocks{chain}: blocks the most recently submitted header trails the chain tip by
 
 */
 
 func keepAliveCmd() *cobra.Command {
 
 	var interval int
+	var timeout int
+	var autoRelay bool
+	var listenAddr string
+	var tlsCert, tlsKey string
+	var statusFile string
 	cmd := &cobra.Command{
-		Use:   "keepAlive [path]",
-		Short: "Keep channel alive",
-		Long:  strings.TrimSpace(`Regularily sends client updates to keep channel alive. Client ID is the same as the one used for 'raw update-client'`),
-		Args:  cobra.ExactArgs(1),
+		Use:   "keepAlive [path...]",
+		Short: "Keep one or more channels alive",
+		Args:  cobra.MinimumNArgs(1),
+		Long: `Regularly sends client updates to keep channels alive. Client IDs are the same as
+the ones used for 'raw update-client'. Multiple paths may be given to monitor them from a
+single process.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			path, err := config.Paths.Get(args[0])
+			reg := prometheus.NewRegistry()
+
+			ka, err := monitor.New(monitor.KeepAliveConfig{
+				Paths:      args,
+				Config:     config,
+				Interval:   time.Duration(interval) * time.Second,
+				Timeout:    time.Duration(timeout) * time.Second,
+				ListenAddr: listenAddr,
+				Reg:        reg,
+				AutoRelay:  autoRelay,
+			})
 			if err != nil {
 				return err
 			}
 
-			srcChainID, srcClientID := path.Src.ChainID, path.Src.ClientID
-			dstChainID, dstClientID := path.Dst.ChainID, path.Dst.ClientID
-
-			unrelayedSeq := prometheus.NewGauge(prometheus.GaugeOpts{
-				Namespace: "GoZ",
-				Subsystem: "relayer",
-				Name:      "unrelayed_sequences",
-				Help:      "number of unrelayed sequences or negative if error occurred",
-			})
-			prometheus.MustRegister(unrelayedSeq)
+			ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
 
-			go repeatedlyCheckUnrelayed(path, 60, unrelayedSeq)
+			ka.Start(ctx)
 
-			scriptHealthSRC := prometheus.NewGauge(prometheus.GaugeOpts{
-				Namespace: "GoZ",
-				Subsystem: "relayer",
-				Name:      "script_health_src",
-				Help:      "0.0 if script is not running successfully, else 1.0",
+			mux := http.NewServeMux()
+			mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+			mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
 			})
-			prometheus.MustRegister(scriptHealthSRC)
-
-			lastUpdateSRC := prometheus.NewGauge(prometheus.GaugeOpts{
-				Namespace: "GoZ",
-				Subsystem: "relayer",
-				Name:      "last_update_src",
-				Help:      "unix timestamp in seconds of when the last update was executed",
+			mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+				if !ka.Ready() {
+					http.Error(w, "not all paths are up to date", http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
 			})
-			prometheus.MustRegister(lastUpdateSRC)
-
-			scriptHealthDST := prometheus.NewGauge(prometheus.GaugeOpts{
-				Namespace: "GoZ",
-				Subsystem: "relayer",
-				Name:      "script_health_dst",
-				Help:      "0.0 if script is not running successfully, else 1.0",
+			mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(ka.Statuses())
 			})
-			prometheus.MustRegister(scriptHealthDST)
 
-			lastUpdateDST := prometheus.NewGauge(prometheus.GaugeOpts{
-				Namespace: "GoZ",
-				Subsystem: "relayer",
-				Name:      "last_update_dst",
-				Help:      "unix timestamp in seconds of when the last update was executed",
-			})
-			prometheus.MustRegister(lastUpdateDST)
-
-			go keepAlive(interval, srcChainID, dstChainID, srcClientID, scriptHealthSRC, lastUpdateSRC)
-			go keepAlive(interval, dstChainID, srcChainID, dstClientID, scriptHealthDST, lastUpdateDST)
+			if statusFile != "" {
+				go writeStatusFileAtInterval(ctx, ka, statusFile, time.Duration(interval)*time.Second)
+			}
 
-			http.Handle("/metrics", promhttp.Handler())
-			return http.ListenAndServe("0.0.0.0:20202", nil)
+			srv := &http.Server{Addr: listenAddr, Handler: mux}
+			go func() {
+				<-ctx.Done()
+
+				shutdownTimeout := time.Duration(timeout) * time.Second
+				if shutdownTimeout <= 0 {
+					shutdownTimeout = 30 * time.Second
+				}
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+				defer cancel()
+
+				ka.Stop(shutdownCtx)
+				srv.Shutdown(shutdownCtx)
+			}()
+
+			if tlsCert != "" || tlsKey != "" {
+				err = srv.ListenAndServeTLS(tlsCert, tlsKey)
+			} else {
+				err = srv.ListenAndServe()
+			}
+			if err != nil && err != http.ErrServerClosed {
+				return err
+			}
+			return nil
 		},
 	}
 
 	cmd.Flags().IntVarP(&interval, "interval", "i", 5390, "interval to run update-client at")
+	cmd.Flags().IntVar(&timeout, "timeout", 30, "timeout in seconds for each per-chain call during a tick, and the grace period on shutdown")
+	cmd.Flags().BoolVar(&autoRelay, "auto-relay", false, "automatically relay any unrelayed packets found on the path")
+	cmd.Flags().StringVar(&listenAddr, "listen", "0.0.0.0:20202", "address the metrics/health server listens on")
+	cmd.Flags().StringVar(&tlsCert, "tls-cert", "", "path to a TLS certificate for the metrics/health server")
+	cmd.Flags().StringVar(&tlsKey, "tls-key", "", "path to the TLS private key for the metrics/health server")
+	cmd.Flags().StringVar(&statusFile, "status-file", "", "path to atomically write the /status JSON to on every tick")
 	return cmd
 }
 
-// keepAlive runs a loop sending a client_update tx every [interval] seconds
-func keepAlive(interval int, srcChainID, dstChainID, clientID string, scriptHealth, lastUpdate prometheus.Gauge) {
-	for {
-		err := runUpdateAtInterval(interval, srcChainID, dstChainID, clientID, scriptHealth, lastUpdate)
-		log.Println("Error on update:", err)
-		time.Sleep(1 * time.Second) // On error will wait a second and then try again ad infinitum
-	}
-}
-
-func runUpdateAtInterval(interval int, srcChainID, dstChainID, clientID string, scriptHealth, lastUpdate prometheus.Gauge) error {
-	ucc := updateClientCmd()
-	args := []string{srcChainID, dstChainID, clientID}
-	t := time.NewTicker(time.Second * time.Duration(interval))
+// writeStatusFileAtInterval writes ka's status to path atomically every interval, until
+// ctx is done, so a supervisor without Prometheus access can react to lastError*.
+func writeStatusFileAtInterval(ctx context.Context, ka *monitor.KeepAlive, path string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 
-	defer t.Stop()
-	defer scriptHealth.Set(0.0) // set to unhealthy when funciton returns, because this only happens on error
-
-	log.Println("Updating Client", clientID)
-	if err := ucc.RunE(nil, args); err != nil {
-		return err
-	}
-
-	scriptHealth.Set(1.0) // is set to healthy after succesfull execution
-	lastUpdate.SetToCurrentTime()
+	for {
+		writeStatusFile(path, ka.Statuses())
 
-	for range t.C {
-		log.Println("Updating Clients")
-		if err := ucc.RunE(nil, args); err != nil {
-			return err
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
 		}
-		lastUpdate.SetToCurrentTime()
-	}
-
-	return errors.New("This shouldn't happen, this function should only return an update error")
-}
-
-func repeatedlyCheckUnrelayed(path *relayer.Path, interval int, unrelayedSeq prometheus.Gauge) {
-	log.Println("Start monitoring of unrelayed sequences")
-	for {
-		unrelayedSeq.Set(float64(checkUnrelayedSequences(path)))
-		time.Sleep(time.Duration(interval) * time.Second)
 	}
 }
 
-// copy of 'query unrelayed', but returns number of unrelayed sequences or -1 on error
-func checkUnrelayedSequences(path *relayer.Path) int {
-	src, dst := path.Src.ChainID, path.Dst.ChainID
-
-	c, err := config.Chains.Gets(src, dst)
+func writeStatusFile(path string, statuses []monitor.Status) {
+	data, err := json.MarshalIndent(statuses, "", "  ")
 	if err != nil {
-		log.Println("Couldn't get unrelayed sequences:", err)
-		return -1
+		log.Println("keepAlive: marshaling status:", err)
+		return
 	}
 
-	if err = c[src].SetPath(path.Src); err != nil {
-		log.Println("Couldn't get unrelayed sequences:", err)
-		return -1
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		log.Println("keepAlive: writing status file:", err)
+		return
 	}
-	if err = c[dst].SetPath(path.Dst); err != nil {
-		log.Println("Couldn't get unrelayed sequences:", err)
-		return -1
+	if err := os.Rename(tmp, path); err != nil {
+		log.Println("keepAlive: renaming status file:", err)
 	}
-
-	sh, err := relayer.NewSyncHeaders(c[src], c[dst])
-	if err != nil {
-		log.Println("Couldn't get unrelayed sequences:", err)
-		return -1
-	}
-
-	sp, err := relayer.UnrelayedSequences(c[src], c[dst], sh)
-	if err != nil {
-		log.Println("Couldn't get unrelayed sequences:", err)
-		return -1
-	}
-
-	total := len(sp.Src) + len(sp.Dst)
-
-	return total
 }
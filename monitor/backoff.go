@@ -0,0 +1,35 @@
+package monitor
+
+import (
+	"math/rand"
+	"time"
+)
+
+// backoff produces exponentially increasing retry delays with jitter, capped at max.
+// It is not safe for concurrent use; each pathPoller owns its own instance.
+type backoff struct {
+	base, max time.Duration
+	attempt   uint
+}
+
+func newBackoff(base, max time.Duration) *backoff {
+	return &backoff{base: base, max: max}
+}
+
+// next returns the delay to wait before the next retry and advances the backoff.
+func (b *backoff) next() time.Duration {
+	d := b.base << b.attempt
+	if d <= 0 || d > b.max {
+		d = b.max
+	} else {
+		b.attempt++
+	}
+
+	// Full jitter: sleep a random duration between 0 and d.
+	return time.Duration(rand.Int63n(int64(d)))
+}
+
+// reset clears accumulated attempts after a successful tick.
+func (b *backoff) reset() {
+	b.attempt = 0
+}
@@ -0,0 +1,50 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffNext(t *testing.T) {
+	cases := []struct {
+		name      string
+		base, max time.Duration
+		advances  int
+	}{
+		{"first attempt is within 0..max", time.Second, 10 * time.Second, 0},
+		{"caps at max after many attempts", time.Second, 4 * time.Second, 10},
+		{"base already at max", 5 * time.Second, 5 * time.Second, 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			b := newBackoff(c.base, c.max)
+
+			for i := 0; i < c.advances; i++ {
+				b.next()
+			}
+
+			for i := 0; i < 20; i++ {
+				if d := b.next(); d < 0 || d > c.max {
+					t.Fatalf("next() = %v, want within [0, %v]", d, c.max)
+				}
+			}
+		})
+	}
+}
+
+func TestBackoffReset(t *testing.T) {
+	b := newBackoff(time.Second, 8*time.Second)
+
+	for i := 0; i < 5; i++ {
+		b.next()
+	}
+	if b.attempt == 0 {
+		t.Fatal("expected attempt to have advanced before reset")
+	}
+
+	b.reset()
+	if b.attempt != 0 {
+		t.Fatalf("attempt = %d after reset, want 0", b.attempt)
+	}
+}
@@ -0,0 +1,198 @@
+// Package monitor implements a config-driven poller that keeps one or more IBC
+// paths' light clients updated and reports their health over Prometheus.
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/iqlusioninc/relayer/relayer"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// KeepAliveConfig configures a KeepAlive poller.
+type KeepAliveConfig struct {
+	// Paths are the names of the configured paths to keep alive, as found in Config.Paths.
+	Paths []string
+	// Config is the relayer configuration used to resolve path names to chains and clients.
+	Config *relayer.Config
+	// Interval is how often each path's clients are updated.
+	Interval time.Duration
+	// Timeout bounds each client update and query performed during a tick.
+	Timeout time.Duration
+	// ListenAddr is the address of the metrics server that serves Reg. KeepAlive does not
+	// read it and starts no server of its own.
+	ListenAddr string
+	// Reg is the registerer gauges are registered against. Defaults to prometheus.DefaultRegisterer.
+	Reg prometheus.Registerer
+	// AutoRelay, when true, submits any unrelayed packets found on a path instead of
+	// only reporting their count.
+	AutoRelay bool
+}
+
+// pathStatus is the latest known state of a single path, guarded by KeepAlive.mu.
+type pathStatus struct {
+	path *relayer.Path
+
+	lastUpdateSrc, lastUpdateDst                     time.Time
+	lastErrorSrc, lastErrorDst                       error
+	unrelayedSrc, unrelayedDst                       int
+	trustPeriodRemainingSrc, trustPeriodRemainingDst time.Duration
+}
+
+// KeepAlive runs one update loop per configured path, keeping each path's IBC clients
+// from expiring and reporting process health over Prometheus.
+type KeepAlive struct {
+	cfg KeepAliveConfig
+
+	mu       sync.RWMutex
+	statuses map[string]*pathStatus
+
+	pollers []*pathPoller
+
+	metrics *metrics
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New validates cfg, resolves every named path and returns a KeepAlive ready to Start.
+func New(cfg KeepAliveConfig) (*KeepAlive, error) {
+	if len(cfg.Paths) == 0 {
+		return nil, fmt.Errorf("monitor: at least one path is required")
+	}
+	if cfg.Interval <= 0 {
+		return nil, fmt.Errorf("monitor: interval must be positive")
+	}
+	if cfg.Reg == nil {
+		cfg.Reg = prometheus.DefaultRegisterer
+	}
+
+	ka := &KeepAlive{
+		cfg:      cfg,
+		statuses: make(map[string]*pathStatus, len(cfg.Paths)),
+		metrics:  newMetrics(cfg.Reg),
+	}
+
+	for _, name := range cfg.Paths {
+		path, err := cfg.Config.Paths.Get(name)
+		if err != nil {
+			return nil, fmt.Errorf("monitor: resolving path %q: %w", name, err)
+		}
+
+		ka.statuses[name] = &pathStatus{path: path}
+		ka.pollers = append(ka.pollers, newPathPoller(ka, name, path))
+	}
+
+	return ka, nil
+}
+
+// Start launches one update loop per path. It returns immediately; call Stop to tear
+// the loops down.
+func (ka *KeepAlive) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	ka.cancel = cancel
+
+	for _, p := range ka.pollers {
+		ka.wg.Add(1)
+		go func(p *pathPoller) {
+			defer ka.wg.Done()
+			p.run(ctx)
+		}(p)
+	}
+}
+
+// Stop cancels every update loop and waits for them to exit, or for ctx to be done first.
+func (ka *KeepAlive) Stop(ctx context.Context) error {
+	if ka.cancel == nil {
+		return nil
+	}
+	ka.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		ka.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Ready reports whether every path has completed a successful client update on both
+// sides within the last two update intervals.
+func (ka *KeepAlive) Ready() bool {
+	ka.mu.RLock()
+	defer ka.mu.RUnlock()
+
+	cutoff := time.Now().Add(-2 * ka.cfg.Interval)
+	for _, st := range ka.statuses {
+		if st.lastUpdateSrc.Before(cutoff) || st.lastUpdateDst.Before(cutoff) {
+			return false
+		}
+	}
+	return true
+}
+
+// Status is a JSON-serializable snapshot of a single path's health.
+type Status struct {
+	Path      string `json:"path"`
+	SrcChain  string `json:"srcChain"`
+	DstChain  string `json:"dstChain"`
+	SrcClient string `json:"srcClient"`
+	DstClient string `json:"dstClient"`
+
+	LastUpdateSrc time.Time `json:"lastUpdateSrc"`
+	LastUpdateDst time.Time `json:"lastUpdateDst"`
+	LastErrorSrc  string    `json:"lastErrorSrc,omitempty"`
+	LastErrorDst  string    `json:"lastErrorDst,omitempty"`
+
+	UnrelayedSrc int `json:"unrelayedSrc"`
+	UnrelayedDst int `json:"unrelayedDst"`
+
+	TrustPeriodRemainingSrc float64 `json:"trustPeriodRemainingSrc"`
+	TrustPeriodRemainingDst float64 `json:"trustPeriodRemainingDst"`
+}
+
+// Statuses returns a snapshot of every path's status, sorted by path name for a
+// stable API response. Timestamps are truncated to second precision.
+func (ka *KeepAlive) Statuses() []Status {
+	ka.mu.RLock()
+	defer ka.mu.RUnlock()
+
+	out := make([]Status, 0, len(ka.statuses))
+	for name, st := range ka.statuses {
+		s := Status{
+			Path:                    name,
+			SrcChain:                st.path.Src.ChainID,
+			DstChain:                st.path.Dst.ChainID,
+			SrcClient:               st.path.Src.ClientID,
+			DstClient:               st.path.Dst.ClientID,
+			LastUpdateSrc:           st.lastUpdateSrc.Truncate(time.Second),
+			LastUpdateDst:           st.lastUpdateDst.Truncate(time.Second),
+			UnrelayedSrc:            st.unrelayedSrc,
+			UnrelayedDst:            st.unrelayedDst,
+			TrustPeriodRemainingSrc: st.trustPeriodRemainingSrc.Seconds(),
+			TrustPeriodRemainingDst: st.trustPeriodRemainingDst.Seconds(),
+		}
+		if st.lastErrorSrc != nil {
+			s.LastErrorSrc = st.lastErrorSrc.Error()
+		}
+		if st.lastErrorDst != nil {
+			s.LastErrorDst = st.lastErrorDst.Error()
+		}
+		out = append(out, s)
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+
+	return out
+}
@@ -0,0 +1,85 @@
+package monitor
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// metrics holds every Prometheus collector the monitor package exposes, registered
+// once against whatever registerer KeepAliveConfig.Reg provides.
+type metrics struct {
+	scriptHealth        *prometheus.GaugeVec
+	lastUpdate          *prometheus.GaugeVec
+	unrelayedSequences  *prometheus.GaugeVec
+	relayedPacketsTotal *prometheus.CounterVec
+	relayDuration       *prometheus.HistogramVec
+
+	trustPeriodRemaining *prometheus.GaugeVec
+	latestHeight         *prometheus.GaugeVec
+	headerLag            *prometheus.HistogramVec
+}
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	m := &metrics{
+		scriptHealth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "GoZ",
+			Subsystem: "relayer",
+			Name:      "script_health",
+			Help:      "0.0 if the client updater is not running successfully, else 1.0",
+		}, []string{"path", "chain", "client"}),
+		lastUpdate: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "GoZ",
+			Subsystem: "relayer",
+			Name:      "last_update",
+			Help:      "unix timestamp in seconds of when the last client update succeeded",
+		}, []string{"path", "chain", "client"}),
+		unrelayedSequences: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "GoZ",
+			Subsystem: "relayer",
+			Name:      "unrelayed_sequences",
+			Help:      "number of unrelayed sequences or negative if an error occurred",
+		}, []string{"path"}),
+		relayedPacketsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "GoZ",
+			Subsystem: "relayer",
+			Name:      "relayed_packets_total",
+			Help:      "total number of packets submitted by the auto-relay feature",
+		}, []string{"path", "direction", "result"}),
+		relayDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "GoZ",
+			Subsystem: "relayer",
+			Name:      "relay_duration_seconds",
+			Help:      "time taken to relay a batch of packets on a path",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"path"}),
+		trustPeriodRemaining: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "GoZ",
+			Subsystem: "relayer",
+			Name:      "client_trust_period_remaining_seconds",
+			Help:      "seconds remaining before the client's trusting period elapses",
+		}, []string{"chain", "client"}),
+		latestHeight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "GoZ",
+			Subsystem: "relayer",
+			Name:      "chain_latest_height",
+			Help:      "latest height observed for the chain",
+		}, []string{"chain"}),
+		headerLag: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "GoZ",
+			Subsystem: "relayer",
+			Name:      "header_lag_blocks",
+			Help:      "difference between the chain tip and the most recently submitted header",
+			Buckets:   prometheus.LinearBuckets(0, 5, 10),
+		}, []string{"chain"}),
+	}
+
+	reg.MustRegister(
+		m.scriptHealth,
+		m.lastUpdate,
+		m.unrelayedSequences,
+		m.relayedPacketsTotal,
+		m.relayDuration,
+		m.trustPeriodRemaining,
+		m.latestHeight,
+		m.headerLag,
+	)
+
+	return m
+}
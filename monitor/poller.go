@@ -0,0 +1,229 @@
+package monitor
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/iqlusioninc/relayer/relayer"
+)
+
+// pathPoller owns the single update loop for one configured path.
+type pathPoller struct {
+	ka   *KeepAlive
+	name string
+	path *relayer.Path
+
+	relayMu sync.Mutex
+}
+
+func newPathPoller(ka *KeepAlive, name string, path *relayer.Path) *pathPoller {
+	return &pathPoller{ka: ka, name: name, path: path}
+}
+
+// run ticks at the configured interval, falling back to an exponential backoff with
+// jitter (capped at the interval) between retries while a path is unhealthy.
+func (p *pathPoller) run(ctx context.Context) {
+	bo := newBackoff(time.Second, p.ka.cfg.Interval)
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		if p.tick(ctx) {
+			bo.reset()
+			timer.Reset(p.ka.cfg.Interval)
+		} else {
+			timer.Reset(bo.next())
+		}
+	}
+}
+
+// tick performs one round of client updates, expiry/lag reporting and an
+// unrelayed-sequences check, reporting whether the client updates, the sync-header
+// fetch and the unrelayed-sequences check all succeeded; failures in expiry/lag
+// reporting are only logged and do not affect the result. Every per-chain
+// call is bounded by cfg.Timeout (if set) so a wedged RPC can't hang the tick, or a
+// shutdown waiting on it, forever.
+func (p *pathPoller) tick(ctx context.Context) bool {
+	src, dst := p.path.Src.ChainID, p.path.Dst.ChainID
+
+	c, err := p.ka.cfg.Config.Chains.Gets(src, dst)
+	if err != nil {
+		log.Printf("monitor: path %s: fetching chains: %v", p.name, err)
+		return false
+	}
+	if err := c[src].SetPath(p.path.Src); err != nil {
+		log.Printf("monitor: path %s: setting src path: %v", p.name, err)
+		return false
+	}
+	if err := c[dst].SetPath(p.path.Dst); err != nil {
+		log.Printf("monitor: path %s: setting dst path: %v", p.name, err)
+		return false
+	}
+
+	okSrc := p.updateClient(ctx, src, dst, p.path.Src.ClientID, c, true)
+	okDst := p.updateClient(ctx, dst, src, p.path.Dst.ClientID, c, false)
+
+	// Built after the client updates above so unrelayed-sequence checks, auto-relay and
+	// the lag gauges all see this tick's freshly submitted headers, not stale ones.
+	sh, err := queryWithTimeout(ctx, p.ka.cfg.Timeout, func() (*relayer.SyncHeaders, error) {
+		return relayer.NewSyncHeaders(c[src], c[dst])
+	})
+	if err != nil {
+		log.Printf("monitor: path %s: building sync headers: %v", p.name, err)
+		p.ka.metrics.unrelayedSequences.WithLabelValues(p.name).Set(-1)
+		return false
+	}
+
+	p.reportClientHealth(ctx, src, dst, p.path.Src.ClientID, c, sh, true)
+	p.reportClientHealth(ctx, dst, src, p.path.Dst.ClientID, c, sh, false)
+
+	okUnrelayed := p.checkUnrelayed(ctx, c, sh, src, dst)
+
+	return okSrc && okDst && okUnrelayed
+}
+
+// updateClient updates the light client for chainID tracking counterpartyID, recording
+// the result in both the shared status struct and the script_health/last_update gauges.
+func (p *pathPoller) updateClient(ctx context.Context, chainID, counterpartyID, clientID string, c map[string]*relayer.Chain, isSrc bool) bool {
+	err := callWithTimeout(ctx, p.ka.cfg.Timeout, func() error {
+		return relayer.UpdateClient(c[chainID], c[counterpartyID])
+	})
+
+	p.ka.mu.Lock()
+	st := p.ka.statuses[p.name]
+	if isSrc {
+		st.lastErrorSrc = err
+		if err == nil {
+			st.lastUpdateSrc = time.Now()
+		}
+	} else {
+		st.lastErrorDst = err
+		if err == nil {
+			st.lastUpdateDst = time.Now()
+		}
+	}
+	p.ka.mu.Unlock()
+
+	labels := []string{p.name, chainID, clientID}
+	if err != nil {
+		log.Printf("monitor: path %s: updating client %s on %s: %v", p.name, clientID, chainID, err)
+		p.ka.metrics.scriptHealth.WithLabelValues(labels...).Set(0.0)
+		return false
+	}
+
+	p.ka.metrics.scriptHealth.WithLabelValues(labels...).Set(1.0)
+	p.ka.metrics.lastUpdate.WithLabelValues(labels...).SetToCurrentTime()
+	return true
+}
+
+// reportClientHealth surfaces how close the client on chainID (tracking counterpartyID)
+// is to expiring, and how far its latest height trails the counterparty's chain tip.
+func (p *pathPoller) reportClientHealth(ctx context.Context, chainID, counterpartyID, clientID string, c map[string]*relayer.Chain, sh *relayer.SyncHeaders, isSrc bool) {
+	cs, err := queryWithTimeout(ctx, p.ka.cfg.Timeout, func() (relayer.TendermintClientState, error) {
+		return c[chainID].QueryTendermintClientState(clientID)
+	})
+	if err != nil {
+		log.Printf("monitor: path %s: querying client state for %s on %s: %v", p.name, clientID, chainID, err)
+		return
+	}
+
+	consState, err := queryWithTimeout(ctx, p.ka.cfg.Timeout, func() (relayer.ConsensusState, error) {
+		return c[chainID].QueryConsensusState(clientID, cs.GetLatestHeight())
+	})
+	if err != nil {
+		log.Printf("monitor: path %s: querying consensus state for %s on %s: %v", p.name, clientID, chainID, err)
+		return
+	}
+
+	remaining := cs.TrustingPeriod - time.Since(consState.Timestamp)
+	p.ka.metrics.trustPeriodRemaining.WithLabelValues(chainID, clientID).Set(remaining.Seconds())
+
+	p.ka.mu.Lock()
+	st := p.ka.statuses[p.name]
+	if isSrc {
+		st.trustPeriodRemainingSrc = remaining
+	} else {
+		st.trustPeriodRemainingDst = remaining
+	}
+	p.ka.mu.Unlock()
+
+	header, err := queryWithTimeout(ctx, p.ka.cfg.Timeout, func() (relayer.Header, error) {
+		return sh.GetHeader(counterpartyID)
+	})
+	if err != nil {
+		log.Printf("monitor: path %s: getting latest header for %s: %v", p.name, counterpartyID, err)
+		return
+	}
+
+	tip := header.GetHeight().GetRevisionHeight()
+	p.ka.metrics.latestHeight.WithLabelValues(counterpartyID).Set(float64(tip))
+
+	lag := int64(tip) - int64(cs.GetLatestHeight().GetRevisionHeight())
+	p.ka.metrics.headerLag.WithLabelValues(counterpartyID).Observe(float64(lag))
+}
+
+// checkUnrelayed reports the number of packets still awaiting relay on path p, and
+// kicks off a relay attempt when auto-relay is enabled.
+func (p *pathPoller) checkUnrelayed(ctx context.Context, c map[string]*relayer.Chain, sh *relayer.SyncHeaders, src, dst string) bool {
+	sp, err := queryWithTimeout(ctx, p.ka.cfg.Timeout, func() (*relayer.RelaySequences, error) {
+		return relayer.UnrelayedSequences(c[src], c[dst], sh)
+	})
+	if err != nil {
+		log.Printf("monitor: path %s: checking unrelayed sequences: %v", p.name, err)
+		p.ka.metrics.unrelayedSequences.WithLabelValues(p.name).Set(-1)
+		return false
+	}
+
+	p.ka.mu.Lock()
+	st := p.ka.statuses[p.name]
+	st.unrelayedSrc = len(sp.Src)
+	st.unrelayedDst = len(sp.Dst)
+	p.ka.mu.Unlock()
+
+	p.ka.metrics.unrelayedSequences.WithLabelValues(p.name).Set(float64(len(sp.Src) + len(sp.Dst)))
+
+	p.maybeRelay(c, src, dst, sh, sp)
+
+	return true
+}
+
+// maybeRelay submits any unrelayed packets found on the path in the background, unless
+// a previous relay attempt for this path is still inflight. The relay call is
+// deliberately not bounded by cfg.Timeout (that's sized for per-chain queries, not a
+// full relay) and relayMu is held for as long as the real call runs, not just until a
+// deadline elapses, so we never release it while a submission is still in flight.
+func (p *pathPoller) maybeRelay(c map[string]*relayer.Chain, src, dst string, sh *relayer.SyncHeaders, sp *relayer.RelaySequences) {
+	if !p.ka.cfg.AutoRelay || len(sp.Src)+len(sp.Dst) == 0 {
+		return
+	}
+	if !p.relayMu.TryLock() {
+		log.Printf("monitor: path %s: relay already in flight, skipping", p.name)
+		return
+	}
+
+	srcCount, dstCount := len(sp.Src), len(sp.Dst)
+	go func() {
+		defer p.relayMu.Unlock()
+
+		start := time.Now()
+		err := relayer.RelayPacketsOrderedChan(c[src], c[dst], sh, sp)
+		p.ka.metrics.relayDuration.WithLabelValues(p.name).Observe(time.Since(start).Seconds())
+
+		result := "success"
+		if err != nil {
+			result = "error"
+			log.Printf("monitor: path %s: relaying packets: %v", p.name, err)
+		}
+		p.ka.metrics.relayedPacketsTotal.WithLabelValues(p.name, "src_to_dst", result).Add(float64(srcCount))
+		p.ka.metrics.relayedPacketsTotal.WithLabelValues(p.name, "dst_to_src", result).Add(float64(dstCount))
+	}()
+}
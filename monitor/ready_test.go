@@ -0,0 +1,28 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKeepAliveReady(t *testing.T) {
+	now := time.Now()
+
+	ka := &KeepAlive{
+		cfg: KeepAliveConfig{Interval: time.Minute},
+		statuses: map[string]*pathStatus{
+			"fresh": {lastUpdateSrc: now, lastUpdateDst: now},
+		},
+	}
+	if !ka.Ready() {
+		t.Fatal("Ready() = false, want true for a path updated within 2x the interval")
+	}
+
+	ka.statuses["stale"] = &pathStatus{
+		lastUpdateSrc: now.Add(-3 * time.Minute),
+		lastUpdateDst: now,
+	}
+	if ka.Ready() {
+		t.Fatal("Ready() = true, want false once a path's src update is stale")
+	}
+}
@@ -0,0 +1,51 @@
+package monitor
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/iqlusioninc/relayer/relayer"
+)
+
+func TestKeepAliveStatuses(t *testing.T) {
+	now := time.Now()
+
+	ka := &KeepAlive{
+		statuses: map[string]*pathStatus{
+			"zeta": {
+				path: &relayer.Path{
+					Src: &relayer.PathEnd{ChainID: "z-src", ClientID: "07-tendermint-9"},
+					Dst: &relayer.PathEnd{ChainID: "z-dst", ClientID: "07-tendermint-10"},
+				},
+				lastUpdateSrc: now,
+				unrelayedSrc:  3,
+			},
+			"alpha": {
+				path: &relayer.Path{
+					Src: &relayer.PathEnd{ChainID: "a-src", ClientID: "07-tendermint-1"},
+					Dst: &relayer.PathEnd{ChainID: "a-dst", ClientID: "07-tendermint-2"},
+				},
+				lastErrorDst: errors.New("boom"),
+			},
+		},
+	}
+
+	got := ka.Statuses()
+
+	if len(got) != 2 {
+		t.Fatalf("len(Statuses()) = %d, want 2", len(got))
+	}
+	if got[0].Path != "alpha" || got[1].Path != "zeta" {
+		t.Fatalf("Statuses() not sorted by path name: %+v", got)
+	}
+	if got[0].LastErrorDst != "boom" {
+		t.Fatalf("LastErrorDst = %q, want %q", got[0].LastErrorDst, "boom")
+	}
+	if want := now.Truncate(time.Second); !got[1].LastUpdateSrc.Equal(want) {
+		t.Fatalf("LastUpdateSrc = %v, want %v", got[1].LastUpdateSrc, want)
+	}
+	if got[1].UnrelayedSrc != 3 {
+		t.Fatalf("UnrelayedSrc = %d, want 3", got[1].UnrelayedSrc)
+	}
+}
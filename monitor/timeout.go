@@ -0,0 +1,48 @@
+package monitor
+
+import (
+	"context"
+	"time"
+)
+
+// callWithTimeout runs fn and, when timeout is positive, bounds it by timeout and ctx, so
+// a single wedged RPC call cannot hang a tick, or a shutdown, forever. When timeout is
+// not positive, fn runs to completion on the calling goroutine and ctx is not consulted.
+// fn is not actually
+// interrupted on timeout since the underlying relayer calls take no context of their
+// own, but the goroutine it leaks is abandoned and its result discarded once the
+// deadline is hit.
+func callWithTimeout(ctx context.Context, timeout time.Duration, fn func() error) error {
+	_, err := queryWithTimeout(ctx, timeout, func() (struct{}, error) {
+		return struct{}{}, fn()
+	})
+	return err
+}
+
+// queryWithTimeout is callWithTimeout for calls that also return a value.
+func queryWithTimeout[T any](ctx context.Context, timeout time.Duration, fn func() (T, error)) (T, error) {
+	if timeout <= 0 {
+		return fn()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type result struct {
+		val T
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		v, err := fn()
+		done <- result{v, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.val, r.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}